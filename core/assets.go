@@ -0,0 +1,184 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// fingerprintTTL is the short-lived Cache-Control value applied to an asset served
+// under its original, un-fingerprinted path.
+const fingerprintTTL = "public, max-age=600"
+
+// AssetManifest maps a public/-relative path (e.g. "css/site.css") to its
+// fingerprinted form ("css/site.abcd1234.css"), rebuilt whenever the
+// public/ directory changes.
+type AssetManifest struct {
+	mu      sync.RWMutex
+	dir     string
+	entries map[string]string
+}
+
+func NewAssetManifest(dir string) *AssetManifest {
+	m := &AssetManifest{dir: dir, entries: map[string]string{}}
+	m.Rebuild()
+	return m
+}
+
+// Rebuild hashes every file under m.dir and (re)writes their fingerprinted
+// copies plus .gz/.br siblings alongside the original, then prunes
+// fingerprinted outputs from the previous Rebuild that no longer match
+// any current file's content so stale hashes don't pile up in public/.
+func (m *AssetManifest) Rebuild() {
+	m.mu.RLock()
+	previous := m.entries
+	m.mu.RUnlock()
+
+	entries := map[string]string{}
+
+	filepath.Walk(m.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || isFingerprinted(path) || strings.HasSuffix(path, ".gz") || strings.HasSuffix(path, ".br") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])[:8]
+
+		rel, _ := filepath.Rel(m.dir, path)
+		rel = filepath.ToSlash(rel)
+		ext := filepath.Ext(rel)
+		fingerprinted := strings.TrimSuffix(rel, ext) + "." + hash + ext
+		entries[rel] = fingerprinted
+
+		fpPath := filepath.Join(m.dir, fingerprinted)
+		_ = os.WriteFile(fpPath, data, 0644)
+		_ = writeCompressed(fpPath, data)
+
+		return nil
+	})
+
+	for rel, oldFingerprinted := range previous {
+		if entries[rel] == oldFingerprinted {
+			continue
+		}
+		oldPath := filepath.Join(m.dir, oldFingerprinted)
+		_ = os.Remove(oldPath)
+		_ = os.Remove(oldPath + ".gz")
+		_ = os.Remove(oldPath + ".br")
+	}
+
+	m.mu.Lock()
+	m.entries = entries
+	m.mu.Unlock()
+}
+
+// isFingerprinted is a best-effort check so Rebuild doesn't hash its own
+// previously written fingerprinted copies: it looks for a second
+// extension segment that is exactly 8 hex characters.
+func isFingerprinted(path string) bool {
+	base := filepath.Base(path)
+	parts := strings.Split(base, ".")
+	if len(parts) < 3 {
+		return false
+	}
+	hash := parts[len(parts)-2]
+	if len(hash) != 8 {
+		return false
+	}
+	for _, c := range hash {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+func writeCompressed(path string, data []byte) error {
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write(data)
+	gw.Close()
+	if err := os.WriteFile(path+".gz", gz.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	var br bytes.Buffer
+	bw := brotli.NewWriter(&br)
+	bw.Write(data)
+	bw.Close()
+	return os.WriteFile(path+".br", br.Bytes(), 0644)
+}
+
+// Asset resolves a public/-relative path to its fingerprinted URL, for
+// use as the `{{ asset "css/site.css" }}` template func.
+func (m *AssetManifest) Asset(path string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if fingerprinted, ok := m.entries[path]; ok {
+		return "/public/" + fingerprinted
+	}
+	return "/public/" + path
+}
+
+// ServeAsset serves a path under public/, negotiating .br/.gz siblings
+// via Accept-Encoding the same way acceptsGzip drives serveStatic, and
+// applying a long-lived immutable Cache-Control to fingerprinted paths.
+func (m *AssetManifest) ServeAsset(w http.ResponseWriter, req *http.Request, relPath string) {
+	fullPath := filepath.Join(m.dir, relPath)
+
+	rootPrefix := filepath.Clean(m.dir) + string(filepath.Separator)
+	if !strings.HasPrefix(fullPath, rootPrefix) {
+		http.NotFound(w, req)
+		return
+	}
+
+	if ext := filepath.Ext(relPath); ext != "" {
+		if ct := mime.TypeByExtension(ext); ct != "" {
+			w.Header().Set("Content-Type", ct)
+		}
+	}
+
+	if isFingerprinted(fullPath) {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", fingerprintTTL)
+	}
+
+	accept := req.Header.Get("Accept-Encoding")
+	if strings.Contains(accept, "br") {
+		if data, err := os.ReadFile(fullPath + ".br"); err == nil {
+			w.Header().Set("Content-Encoding", "br")
+			w.Write(data)
+			return
+		}
+	}
+	if strings.Contains(accept, "gzip") {
+		if data, err := os.ReadFile(fullPath + ".gz"); err == nil {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(data)
+			return
+		}
+	}
+
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+	w.Write(data)
+}
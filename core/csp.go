@@ -0,0 +1,108 @@
+package core
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CSPDirectives maps a Content-Security-Policy directive (e.g.
+// "script-src") to the list of sources it allows. Config.CSP is built
+// from this table; a nil/empty table means no CSP header is sent.
+type CSPDirectives map[string][]string
+
+// buildCSPHeader renders the directive table into a single
+// Content-Security-Policy header value, injecting nonce into any
+// "script-src"/"style-src" directive that's present. When the table has
+// no "script-src" (e.g. it's built from a lone "default-src"), a "script-src" is
+// synthesized from "default-src"'s sources plus the nonce, since
+// "default-src" alone never grants an inline nonce — otherwise the
+// dev live-reload <script nonce="..."> would be blocked.
+func buildCSPHeader(directives CSPDirectives, nonce string) string {
+	dirs := make(map[string][]string, len(directives))
+	for k, v := range directives {
+		dirs[k] = append([]string{}, v...)
+	}
+
+	if nonce != "" {
+		nonceSrc := fmt.Sprintf("'nonce-%s'", nonce)
+		for _, key := range []string{"script-src", "style-src"} {
+			if _, ok := dirs[key]; ok {
+				dirs[key] = append(dirs[key], nonceSrc)
+			}
+		}
+		if _, ok := dirs["script-src"]; !ok {
+			dirs["script-src"] = append(append([]string{}, dirs["default-src"]...), nonceSrc)
+		}
+	}
+
+	var parts []string
+	for directive, sources := range dirs {
+		parts = append(parts, directive+" "+strings.Join(sources, " "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// buildCSPHeaderWithHashes renders the directive table using fixed hash
+// sources instead of a nonce, for the build-time/cache mode where the
+// page is served identically to every visitor.
+func buildCSPHeaderWithHashes(directives CSPDirectives, hashes []string) string {
+	var parts []string
+	for directive, sources := range directives {
+		values := append([]string{}, sources...)
+		if (directive == "script-src" || directive == "style-src") && len(hashes) > 0 {
+			values = append(values, hashes...)
+		}
+		parts = append(parts, directive+" "+strings.Join(values, " "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// newNonce generates a fresh random base64 nonce for a single response.
+func newNonce() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+var (
+	inlineScriptOrStyleRe = regexp.MustCompile(`(?s)<(?:script|style)([^>]*)>(.*?)</(?:script|style)>`)
+	srcAttrRe             = regexp.MustCompile(`(?i)\bsrc\s*=`)
+	nonceAttrRe           = regexp.MustCompile(`(?i)\s+nonce\s*=\s*(?:"[^"]*"|'[^']*')`)
+)
+
+// hashInlineBlocks scans rendered HTML for inline <script>/<style> blocks
+// (ones with no "src" attribute) and returns their sha256-base64 CSP hash
+// sources, so a cached prod copy can ship a strict CSP without
+// per-request nonces. Tags carrying "src" (external scripts/stylesheets,
+// whose body is empty or irrelevant) are skipped; a hash is computed on
+// a block's body regardless of whether it also carries a nonce
+// attribute, since the CSP hash source matches a script's content
+// independent of its nonce attribute. stripNonceAttrs should still be
+// run over the cached copy so it doesn't ship the nonce baked into the
+// page that was live-rendered to produce it.
+func hashInlineBlocks(html []byte) []string {
+	var hashes []string
+	for _, match := range inlineScriptOrStyleRe.FindAllSubmatch(html, -1) {
+		attrs, body := match[1], match[2]
+		if srcAttrRe.Match(attrs) {
+			continue
+		}
+		sum := sha256.Sum256(body)
+		hashes = append(hashes, fmt.Sprintf("'sha256-%s'", base64.StdEncoding.EncodeToString(sum[:])))
+	}
+	return hashes
+}
+
+// stripNonceAttrs removes any nonce="..." attribute from the HTML before
+// it's written to the on-disk cache, since that nonce was only valid for
+// the single live render that produced the cached copy and the cached
+// copy's CSP is hash-based, not nonce-based.
+func stripNonceAttrs(html []byte) []byte {
+	return nonceAttrRe.ReplaceAll(html, nil)
+}
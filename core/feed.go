@@ -0,0 +1,196 @@
+package core
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FeedEntry is the data a route contributes to a feed, pulled from the
+// optional keys returned by its index.server.go.
+type FeedEntry struct {
+	URL       string
+	Title     string
+	Summary   string
+	Author    string
+	Published time.Time
+	Updated   time.Time
+}
+
+// FeedBuilder renders Atom 1.0 and RSS 2.0 feeds from a directory of
+// routes, alongside Router's HTML rendering.
+type FeedBuilder struct {
+	config Config
+}
+
+func NewFeedBuilder(config Config) *FeedBuilder {
+	return &FeedBuilder{config: config}
+}
+
+// collectEntries walks routeDir for every index.server.go and asks each
+// index.server.go for feed metadata via the same entry point Router uses
+// to render pages. Routes that don't return Title are skipped, since a
+// feed entry without a title isn't worth publishing; routes that don't
+// return Published are also skipped, since there's no sane date to sort
+// or publish them by. The result is sorted newest-Published-first, as
+// feed readers expect.
+func (b *FeedBuilder) collectEntries(routeDir string) ([]FeedEntry, error) {
+	var entries []FeedEntry
+
+	err := filepath.Walk(routeDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		if filepath.Base(path) != "index.server.go" {
+			return nil
+		}
+
+		data, err := ExecuteServerFile(path, map[string]string{}, false)
+		if err != nil {
+			return nil
+		}
+
+		title, _ := data["Title"].(string)
+		if title == "" {
+			return nil
+		}
+
+		published := timeField(data, "Published")
+		if published.IsZero() {
+			return nil
+		}
+
+		entry := FeedEntry{
+			URL:       "/" + strings.TrimSuffix(strings.TrimPrefix(filepath.Dir(path), "routes/"), "/"),
+			Title:     title,
+			Summary:   stringField(data, "Summary"),
+			Author:    stringField(data, "Author"),
+			Published: published,
+		}
+		entry.Updated = timeField(data, "Updated")
+		if entry.Updated.IsZero() {
+			entry.Updated = entry.Published
+		}
+
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Published.After(entries[j].Published)
+	})
+
+	return entries, nil
+}
+
+func stringField(data map[string]interface{}, key string) string {
+	s, _ := data[key].(string)
+	return s
+}
+
+func timeField(data map[string]interface{}, key string) time.Time {
+	switch v := data[key].(type) {
+	case time.Time:
+		return v
+	case string:
+		t, _ := time.Parse(time.RFC3339, v)
+		return t
+	default:
+		return time.Time{}
+	}
+}
+
+// tagURI builds a stable "tag:" URI (RFC 4151) for a feed entry, seeded
+// from the domain and the date the domain started publishing, so entry
+// IDs don't change across rebuilds or domain moves.
+func tagURI(domain string, start time.Time, path string) string {
+	return fmt.Sprintf("tag:%s,%s:%s", domain, start.Format("2006-01-02"), path)
+}
+
+// BuildAtom renders an Atom 1.0 feed for every entry found under routeDir.
+func (b *FeedBuilder) BuildAtom(routeDir string) ([]byte, error) {
+	entries, err := b.collectEntries(routeDir)
+	if err != nil {
+		return nil, fmt.Errorf("collect feed entries: %w", err)
+	}
+
+	updated := b.config.DomainStartDate
+	for _, e := range entries {
+		if e.Updated.After(updated) {
+			updated = e.Updated
+		}
+	}
+
+	var buf strings.Builder
+	buf.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	buf.WriteString(`<feed xmlns="http://www.w3.org/2005/Atom">` + "\n")
+	fmt.Fprintf(&buf, "  <id>%s</id>\n", tagURI(b.config.OriginalDomain, b.config.DomainStartDate, routeDir))
+	fmt.Fprintf(&buf, "  <title>%s</title>\n", template.HTMLEscapeString(b.config.OriginalDomain))
+	fmt.Fprintf(&buf, "  <updated>%s</updated>\n", updated.Format(time.RFC3339))
+	fmt.Fprintf(&buf, `  <link href="https://%s%s" rel="self"/>`+"\n", b.config.OriginalDomain, b.config.FeedAtomURL)
+	if b.config.FeedAuthor != "" {
+		fmt.Fprintf(&buf, "  <author><name>%s</name></author>\n", template.HTMLEscapeString(b.config.FeedAuthor))
+	}
+
+	for _, e := range entries {
+		url := fmt.Sprintf("https://%s%s", b.config.OriginalDomain, e.URL)
+		buf.WriteString("  <entry>\n")
+		fmt.Fprintf(&buf, "    <id>%s</id>\n", tagURI(b.config.OriginalDomain, b.config.DomainStartDate, e.URL))
+		fmt.Fprintf(&buf, "    <title>%s</title>\n", template.HTMLEscapeString(e.Title))
+		fmt.Fprintf(&buf, `    <link href="%s"/>`+"\n", url)
+		fmt.Fprintf(&buf, "    <updated>%s</updated>\n", e.Updated.Format(time.RFC3339))
+		fmt.Fprintf(&buf, "    <published>%s</published>\n", e.Published.Format(time.RFC3339))
+		if e.Author != "" {
+			fmt.Fprintf(&buf, "    <author><name>%s</name></author>\n", template.HTMLEscapeString(e.Author))
+		}
+		if e.Summary != "" {
+			fmt.Fprintf(&buf, "    <summary>%s</summary>\n", template.HTMLEscapeString(e.Summary))
+		}
+		buf.WriteString("  </entry>\n")
+	}
+
+	buf.WriteString("</feed>\n")
+	return []byte(buf.String()), nil
+}
+
+// BuildRSS renders an RSS 2.0 feed for every entry found under routeDir.
+func (b *FeedBuilder) BuildRSS(routeDir string) ([]byte, error) {
+	entries, err := b.collectEntries(routeDir)
+	if err != nil {
+		return nil, fmt.Errorf("collect feed entries: %w", err)
+	}
+
+	var buf strings.Builder
+	buf.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	buf.WriteString(`<rss version="2.0"><channel>` + "\n")
+	fmt.Fprintf(&buf, "  <title>%s</title>\n", template.HTMLEscapeString(b.config.OriginalDomain))
+	fmt.Fprintf(&buf, "  <link>https://%s</link>\n", b.config.OriginalDomain)
+	description := b.config.FeedDescription
+	if description == "" {
+		description = b.config.OriginalDomain
+	}
+	fmt.Fprintf(&buf, "  <description>%s</description>\n", template.HTMLEscapeString(description))
+
+	for _, e := range entries {
+		url := fmt.Sprintf("https://%s%s", b.config.OriginalDomain, e.URL)
+		buf.WriteString("  <item>\n")
+		fmt.Fprintf(&buf, "    <title>%s</title>\n", template.HTMLEscapeString(e.Title))
+		fmt.Fprintf(&buf, "    <link>%s</link>\n", url)
+		fmt.Fprintf(&buf, "    <guid isPermaLink=\"false\">%s</guid>\n", tagURI(b.config.OriginalDomain, b.config.DomainStartDate, e.URL))
+		fmt.Fprintf(&buf, "    <pubDate>%s</pubDate>\n", e.Published.Format(time.RFC1123Z))
+		if e.Summary != "" {
+			fmt.Fprintf(&buf, "    <description>%s</description>\n", template.HTMLEscapeString(e.Summary))
+		}
+		buf.WriteString("  </item>\n")
+	}
+
+	buf.WriteString("</channel></rss>\n")
+	return []byte(buf.String()), nil
+}
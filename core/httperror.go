@@ -0,0 +1,27 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// HTTPError lets an index.server.go return an arbitrary status instead of
+// the existing not-found sentinel, e.g. `return nil, core.HTTPError{Status:
+// 403, Message: "forbidden"}`. serveStatic routes it to renderErrorPage.
+type HTTPError struct {
+	Status  int
+	Message string
+}
+
+func (e HTTPError) Error() string {
+	return fmt.Sprintf("%d: %s", e.Status, e.Message)
+}
+
+// AsHTTPError reports whether err is (or wraps) an HTTPError, returning it.
+func AsHTTPError(err error) (HTTPError, bool) {
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr, true
+	}
+	return HTTPError{}, false
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Reloader is a pluggable dev live-reload transport. It serves its own
+// handshake endpoint and injects whatever client script that endpoint
+// needs into rendered HTML.
+type Reloader interface {
+	// Handler serves the reload endpoint (e.g. a WebSocket upgrade or an
+	// SSE stream) registered at /__barry_reload.
+	Handler(w http.ResponseWriter, req *http.Request)
+	// Script returns the <script> block, with the given CSP nonce applied,
+	// followed by a closing </body> tag; the page's first </body> is
+	// replaced with it.
+	Script(nonce string) string
+	// Broadcast notifies every connected subscriber that a reload is due.
+	Broadcast()
+}
+
+// WSReloader is the original WebSocket-based reloader.
+type WSReloader struct{}
+
+func NewWSReloader() *WSReloader { return &WSReloader{} }
+
+func (w *WSReloader) Handler(rw http.ResponseWriter, req *http.Request) {
+	// The WebSocket upgrade itself lives outside core's dependency set;
+	// callers that select "ws" are expected to supply a handler that
+	// upgrades the connection and writes "reload" on change.
+	http.Error(rw, "ws reloader requires an upgrader", http.StatusNotImplemented)
+}
+
+func (w *WSReloader) Script(nonce string) string {
+	return fmt.Sprintf(`
+<script nonce="%s">
+	if (typeof WebSocket !== "undefined") {
+		const ws = new WebSocket("ws://" + location.host + "/__barry_reload");
+		ws.onmessage = e => {
+			if (e.data === "reload") location.reload();
+		};
+	}
+</script>
+</body>`, nonce)
+}
+
+func (w *WSReloader) Broadcast() {}
+
+// SSEReloader broadcasts reloads over text/event-stream, avoiding the
+// ws:// vs wss:// protocol mismatch that trips up WebSocket reload behind
+// plain HTTPS proxies, and needing no upgrade handshake.
+type SSEReloader struct {
+	mu          sync.Mutex
+	subscribers map[chan string]struct{}
+}
+
+func NewSSEReloader() *SSEReloader {
+	return &SSEReloader{subscribers: map[chan string]struct{}{}}
+}
+
+func (s *SSEReloader) Handler(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := make(chan string, 1)
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case msg := <-ch:
+			fmt.Fprintf(w, "data: %s\n\n", msg)
+			flusher.Flush()
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *SSEReloader) Script(nonce string) string {
+	return fmt.Sprintf(`
+<script nonce="%s">
+	if (typeof EventSource !== "undefined") {
+		const es = new EventSource("/__barry_reload");
+		es.onmessage = e => {
+			if (e.data === "reload") location.reload();
+		};
+	}
+</script>
+</body>`, nonce)
+}
+
+func (s *SSEReloader) Broadcast() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subscribers {
+		select {
+		case ch <- "reload":
+		default:
+		}
+	}
+}
@@ -23,16 +23,21 @@ type Route struct {
 }
 
 type Router struct {
-	config   Config
-	env      string
-	onReload func()
-	routes   []Route
+	config      Config
+	env         string
+	onReload    func()
+	routes      []Route
+	feedBuilder *FeedBuilder
+	reloader    Reloader
+	stopWatch   chan struct{}
+	assets      *AssetManifest
 }
 
 type RuntimeContext struct {
 	Env         string
 	EnableWatch bool
 	OnReload    func()
+	Reloader    Reloader
 }
 
 type statusRecorder struct {
@@ -58,22 +63,51 @@ func NewRouter(config Config, ctx RuntimeContext) *Router {
 		env:      ctx.Env,
 		onReload: ctx.OnReload,
 	}
+	if config.FeedRoutes != "" {
+		r.feedBuilder = NewFeedBuilder(config)
+	}
+	r.assets = NewAssetManifest("public")
+	if ctx.Env == "dev" {
+		r.reloader = ctx.Reloader
+		if r.reloader == nil {
+			r.reloader = NewSSEReloader()
+		}
+	}
 	r.loadRoutes()
+	r.rebuildSitemapCache()
 
 	if ctx.EnableWatch {
+		r.stopWatch = make(chan struct{})
 		go r.watchEverything()
 	}
 
 	return r
 }
 
+// Close stops the filesystem watcher started by NewRouter, if any. Serve
+// calls this once the HTTP server has finished draining in-flight
+// requests so a graceful shutdown doesn't leak the watcher goroutine.
+func (r *Router) Close() {
+	if r.stopWatch != nil {
+		close(r.stopWatch)
+	}
+}
+
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	start := time.Now()
 	path := strings.Trim(req.URL.Path, "/")
 
 	recorder := &statusRecorder{ResponseWriter: w, status: 200}
 
-	if path == "" {
+	if strings.HasPrefix(path, "public/") {
+		r.assets.ServeAsset(recorder, req, strings.TrimPrefix(path, "public/"))
+	} else if r.reloader != nil && path == "__barry_reload" {
+		r.reloader.Handler(w, req)
+	} else if r.feedBuilder != nil && ((r.config.FeedAtomURL != "" && path == strings.TrimPrefix(r.config.FeedAtomURL, "/")) || (r.config.FeedRSSURL != "" && path == strings.TrimPrefix(r.config.FeedRSSURL, "/"))) {
+		r.serveFeed(recorder, path)
+	} else if path == "sitemap.xml" {
+		r.serveSitemap(recorder)
+	} else if path == "" {
 		r.serveStatic("routes/index.html", "routes/index.server.go", recorder, req, map[string]string{}, "")
 	} else {
 		found := false
@@ -89,7 +123,7 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			}
 		}
 		if !found {
-			renderErrorPage(recorder, r.config, r.env, http.StatusNotFound, "Page not found", req.URL.Path)
+			renderErrorPage(recorder, r.config, r.env, http.StatusNotFound, "Page not found", req.URL.Path, r.assets)
 
 		}
 	}
@@ -101,8 +135,16 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 }
 
 func (r *Router) serveStatic(htmlPath, serverPath string, w http.ResponseWriter, req *http.Request, params map[string]string, resolvedPath string) {
+	if r.config.PanicHandler {
+		defer func() {
+			if rec := recover(); rec != nil {
+				renderErrorPage(w, r.config, r.env, http.StatusInternalServerError, fmt.Sprintf("%v", rec), req.URL.Path, r.assets)
+			}
+		}()
+	}
+
 	if _, err := os.Stat(htmlPath); err != nil {
-		renderErrorPage(w, r.config, r.env, http.StatusNotFound, "Page not found", req.URL.Path)
+		renderErrorPage(w, r.config, r.env, http.StatusNotFound, "Page not found", req.URL.Path, r.assets)
 		return
 	}
 
@@ -113,11 +155,16 @@ func (r *Router) serveStatic(htmlPath, serverPath string, w http.ResponseWriter,
 		htmlPath := filepath.Join(cacheDir, "index.html")
 		gzPath := htmlPath + ".gz"
 
+		cachedCSP := r.cachedCSPHeader(cacheDir)
+
 		if r.env == "prod" && acceptsGzip(req) {
 			if _, err := os.Stat(gzPath); err == nil {
 				data, _ := os.ReadFile(gzPath)
 				w.Header().Set("Content-Encoding", "gzip")
 				w.Header().Set("Content-Type", "text/html")
+				if cachedCSP != "" {
+					w.Header().Set("Content-Security-Policy", cachedCSP)
+				}
 				if r.config.DebugHeaders {
 					w.Header().Set("X-Barry-Cache", "HIT")
 				}
@@ -129,6 +176,9 @@ func (r *Router) serveStatic(htmlPath, serverPath string, w http.ResponseWriter,
 		if _, err := os.Stat(htmlPath); err == nil {
 			data, _ := os.ReadFile(htmlPath)
 			w.Header().Set("Content-Type", "text/html")
+			if cachedCSP != "" {
+				w.Header().Set("Content-Security-Policy", cachedCSP)
+			}
 			if r.config.DebugHeaders {
 				w.Header().Set("X-Barry-Cache", "HIT")
 			}
@@ -153,7 +203,11 @@ func (r *Router) serveStatic(htmlPath, serverPath string, w http.ResponseWriter,
 		result, err := ExecuteServerFile(serverPath, params, r.env == "dev")
 		if err != nil {
 			if IsNotFoundError(err) {
-				renderErrorPage(w, r.config, r.env, http.StatusNotFound, "Page not found", req.URL.Path)
+				renderErrorPage(w, r.config, r.env, http.StatusNotFound, "Page not found", req.URL.Path, r.assets)
+				return
+			}
+			if httpErr, ok := AsHTTPError(err); ok {
+				renderErrorPage(w, r.config, r.env, httpErr.Status, httpErr.Message, req.URL.Path, r.assets)
 				return
 			}
 			http.Error(w, "Server logic error: "+err.Error(), http.StatusInternalServerError)
@@ -178,8 +232,14 @@ func (r *Router) serveStatic(htmlPath, serverPath string, w http.ResponseWriter,
 	tmplFiles = append(tmplFiles, htmlPath)
 	tmplFiles = append(tmplFiles, componentFiles...)
 
-	tmpl := template.New("").Funcs(BarryTemplateFuncs(r.env, r.config.OutputDir))
-	tmpl, err := tmpl.ParseFiles(tmplFiles...)
+	nonce, err := newNonce()
+	if err != nil {
+		http.Error(w, "Nonce error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.New("").Funcs(BarryTemplateFuncs(r.env, r.config.OutputDir, nonce, r.assets))
+	tmpl, err = tmpl.ParseFiles(tmplFiles...)
 	if err != nil {
 		http.Error(w, "Template error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -196,31 +256,91 @@ func (r *Router) serveStatic(htmlPath, serverPath string, w http.ResponseWriter,
 
 	html := rendered.Bytes()
 
-	if r.env == "dev" {
-		liveReloadScript := `
-<script>
-	if (typeof WebSocket !== "undefined") {
-		const ws = new WebSocket("ws://" + location.host + "/__barry_reload");
-		ws.onmessage = e => {
-			if (e.data === "reload") location.reload();
-		};
-	}
-</script>
-</body>`
-		html = bytes.Replace(html, []byte("</body>"), []byte(liveReloadScript), 1)
+	if r.env == "dev" && r.reloader != nil {
+		html = bytes.Replace(html, []byte("</body>"), []byte(r.reloader.Script(nonce)), 1)
 	}
 
 	if r.config.CacheEnabled {
-		_ = SaveCachedHTML(r.config, routeKey, html)
+		cachedHTML := stripNonceAttrs(html)
+		_ = SaveCachedHTML(r.config, routeKey, cachedHTML)
+		_ = r.saveCachedCSP(filepath.Join(r.config.OutputDir, routeKey), cachedHTML)
 	}
 
 	w.Header().Set("Content-Type", "text/html")
+	if len(r.config.CSP) > 0 {
+		w.Header().Set("Content-Security-Policy", buildCSPHeader(r.config.CSP, nonce))
+	}
 	if r.config.DebugHeaders {
 		w.Header().Set("X-Barry-Cache", "MISS")
 	}
 	w.Write(html)
 }
 
+// saveCachedCSP hashes the inline <script>/<style> blocks of a rendered,
+// cached page and writes them as a strict CSP header value next to the
+// cached HTML, so cached prod copies don't need per-request nonces.
+func (r *Router) saveCachedCSP(cacheDir string, html []byte) error {
+	if len(r.config.CSP) == 0 {
+		return nil
+	}
+	hashes := hashInlineBlocks(html)
+	header := buildCSPHeaderWithHashes(r.config.CSP, hashes)
+	return os.WriteFile(filepath.Join(cacheDir, "csp.txt"), []byte(header), 0644)
+}
+
+// cachedCSPHeader reads back the CSP header saved by saveCachedCSP, if any.
+func (r *Router) cachedCSPHeader(cacheDir string) string {
+	data, err := os.ReadFile(filepath.Join(cacheDir, "csp.txt"))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// serveFeed renders (or serves from cache) the Atom or RSS feed for
+// r.config.FeedRoutes. With CacheEnabled the rendered XML is written to
+// OutputDir and served from there on later requests; the feed is always
+// sent uncompressed, whatever the request's Accept-Encoding.
+func (r *Router) serveFeed(w http.ResponseWriter, path string) {
+	isAtom := path == strings.TrimPrefix(r.config.FeedAtomURL, "/")
+
+	cacheName := "feed.xml"
+	contentType := "application/rss+xml"
+	if isAtom {
+		cacheName = "feed.atom"
+		contentType = "application/atom+xml"
+	}
+
+	if r.config.CacheEnabled {
+		cachePath := filepath.Join(r.config.OutputDir, cacheName)
+		if data, err := os.ReadFile(cachePath); err == nil {
+			w.Header().Set("Content-Type", contentType)
+			w.Write(data)
+			return
+		}
+	}
+
+	var xml []byte
+	var err error
+	if isAtom {
+		xml, err = r.feedBuilder.BuildAtom(r.config.FeedRoutes)
+	} else {
+		xml, err = r.feedBuilder.BuildRSS(r.config.FeedRoutes)
+	}
+	if err != nil {
+		http.Error(w, "Feed error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if r.config.CacheEnabled {
+		_ = os.MkdirAll(r.config.OutputDir, 0755)
+		_ = os.WriteFile(filepath.Join(r.config.OutputDir, cacheName), xml, 0644)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(xml)
+}
+
 func (r *Router) loadRoutes() {
 	r.routes = []Route{}
 
@@ -263,9 +383,15 @@ func (r *Router) loadRoutes() {
 	})
 }
 
-func renderErrorPage(w http.ResponseWriter, config Config, env string, status int, message, path string) {
+func renderErrorPage(w http.ResponseWriter, config Config, env string, status int, message, path string, assets *AssetManifest) {
+	nonce, err := newNonce()
+	if err != nil {
+		nonce = ""
+	}
+
 	base := "routes/_error"
 	statusFile := fmt.Sprintf("%s/%d.html", base, status)
+	classFile := fmt.Sprintf("%s/%dxx.html", base, status/100)
 	defaultFile := fmt.Sprintf("%s/index.html", base)
 
 	context := map[string]interface{}{
@@ -308,7 +434,7 @@ func renderErrorPage(w http.ResponseWriter, config Config, env string, status in
 			return nil
 		})
 
-		tmpl := template.New("").Funcs(BarryTemplateFuncs(env, config.OutputDir))
+		tmpl := template.New("").Funcs(BarryTemplateFuncs(env, config.OutputDir, nonce, assets))
 		tmpl, err = tmpl.ParseFiles(tmplFiles...)
 		if err != nil {
 			fmt.Println("❌ Error parsing error page:", err)
@@ -324,7 +450,7 @@ func renderErrorPage(w http.ResponseWriter, config Config, env string, status in
 		return true
 	}
 
-	if tryRender(statusFile) || tryRender(defaultFile) {
+	if tryRender(statusFile) || tryRender(classFile) || tryRender(defaultFile) {
 		return
 	}
 
@@ -356,16 +482,25 @@ func (r *Router) watchEverything() {
 
 	for {
 		select {
+		case <-r.stopWatch:
+			return
 		case event, ok := <-watcher.Events:
 			if !ok {
 				return
 			}
 
 			if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) != 0 {
+				if strings.HasPrefix(event.Name, "public"+string(filepath.Separator)) || strings.HasPrefix(event.Name, "public/") {
+					r.assets.Rebuild()
+				}
 				r.loadRoutes()
 				addDirs()
+				r.rebuildSitemapCache()
 				if r.env == "dev" {
 					println("🔄 Change detected:", event.Name)
+					if r.reloader != nil {
+						r.reloader.Broadcast()
+					}
 					if r.onReload != nil {
 						r.onReload()
 					}
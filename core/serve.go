@@ -0,0 +1,75 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// ShutdownTimeout bounds how long Serve waits for in-flight requests to
+// drain once a shutdown signal arrives.
+const ShutdownTimeout = 10 * time.Second
+
+// listenFDsStart is the first inherited file descriptor under the
+// systemd/einhorn socket-activation convention.
+const listenFDsStart = 3
+
+// Serve runs an HTTP server around r, installing SIGINT/SIGTERM handlers
+// that drain in-flight requests via http.Server.Shutdown before
+// returning. If the environment advertises an inherited listener via
+// LISTEN_FDS (systemd/einhorn style), that socket is adopted instead of
+// binding addr, so a parent process can hand off the listener to a
+// freshly started child for zero-downtime restarts.
+func Serve(ctx context.Context, addr string, r *Router) error {
+	server := &http.Server{Addr: addr, Handler: r}
+
+	listener, err := listenerFor(addr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	shutdownErr := server.Shutdown(shutdownCtx)
+	r.Close()
+
+	if shutdownErr != nil {
+		return fmt.Errorf("shutdown: %w", shutdownErr)
+	}
+	return nil
+}
+
+// listenerFor adopts an inherited LISTEN_FDS socket when one is present,
+// falling back to binding addr directly.
+func listenerFor(addr string) (net.Listener, error) {
+	if n, _ := strconv.Atoi(os.Getenv("LISTEN_FDS")); n > 0 {
+		file := os.NewFile(uintptr(listenFDsStart), "listenfd")
+		return net.FileListener(file)
+	}
+	return net.Listen("tcp", addr)
+}
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plugin"
+	"strings"
+	"time"
+)
+
+// sitemapURLSet renders a minimal sitemap.xml body for the given URLs.
+func sitemapURLSet(urls []sitemapURL) []byte {
+	var buf strings.Builder
+	buf.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	buf.WriteString(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n")
+	for _, u := range urls {
+		buf.WriteString("  <url>\n")
+		fmt.Fprintf(&buf, "    <loc>%s</loc>\n", u.Loc)
+		if !u.LastMod.IsZero() {
+			fmt.Fprintf(&buf, "    <lastmod>%s</lastmod>\n", u.LastMod.Format("2006-01-02"))
+		}
+		buf.WriteString("  </url>\n")
+	}
+	buf.WriteString("</urlset>\n")
+	return []byte(buf.String())
+}
+
+type sitemapURL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// buildSitemap walks r.routes and emits one <url> per static route,
+// skipping dynamic (`_param`) routes unless their index.server.go
+// exports Paths() []map[string]string to pre-materialize concrete URLs.
+func (r *Router) buildSitemap() []byte {
+	var urls []sitemapURL
+
+	for _, route := range r.routes {
+		lastmod := newestMTime(route.HTMLPath, route.ServerPath, r.layoutPathFor(route.HTMLPath))
+
+		if len(route.ParamKeys) == 0 {
+			urls = append(urls, sitemapURL{
+				Loc:     "https://" + r.config.OriginalDomain + "/" + strings.TrimPrefix(strings.TrimPrefix(route.FilePath, "routes"), "/"),
+				LastMod: lastmod,
+			})
+			continue
+		}
+
+		paths, err := ExecuteServerFilePaths(route.ServerPath)
+		if err != nil {
+			continue
+		}
+		for _, p := range paths {
+			loc := route.URLPattern.String()
+			for _, key := range route.ParamKeys {
+				loc = strings.Replace(loc, "([^/]+)", p[key], 1)
+			}
+			loc = strings.Trim(strings.TrimPrefix(strings.TrimSuffix(loc, "$"), "^"), "/")
+			urls = append(urls, sitemapURL{
+				Loc:     "https://" + r.config.OriginalDomain + "/" + loc,
+				LastMod: lastmod,
+			})
+		}
+	}
+
+	return sitemapURLSet(urls)
+}
+
+// ExecuteServerFilePaths loads an index.server.go's optional
+// `Paths() []map[string]string` enumerator and calls it, for routes
+// whose path segments are dynamic ("_param") and therefore need to
+// pre-materialize concrete URLs for the sitemap. The file is built as a
+// Go plugin (same class of mechanism ExecuteServerFile uses to run a
+// route's server code) and the Paths symbol is looked up by name; a
+// route whose server file has no Paths func is simply skipped.
+func ExecuteServerFilePaths(serverPath string) ([]map[string]string, error) {
+	soPath := serverPath + ".paths.so"
+	defer os.Remove(soPath)
+
+	build := exec.Command("go", "build", "-buildmode=plugin", "-o", soPath, serverPath)
+	if out, err := build.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("build %s for Paths(): %w: %s", serverPath, err, out)
+	}
+
+	p, err := plugin.Open(soPath)
+	if err != nil {
+		return nil, fmt.Errorf("open plugin for %s: %w", serverPath, err)
+	}
+
+	sym, err := p.Lookup("Paths")
+	if err != nil {
+		return nil, nil
+	}
+
+	pathsFunc, ok := sym.(func() []map[string]string)
+	if !ok {
+		return nil, fmt.Errorf("%s: Paths has the wrong signature", serverPath)
+	}
+
+	return pathsFunc(), nil
+}
+
+// layoutPathFor extracts the `<!-- layout: ... -->` path declared by an
+// HTML route, if any, so its mtime can feed into <lastmod>.
+func (r *Router) layoutPathFor(htmlPath string) string {
+	content, err := os.ReadFile(htmlPath)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "<!-- layout:") && strings.HasSuffix(line, "-->") {
+			return strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "<!-- layout:"), "-->"))
+		}
+	}
+	return ""
+}
+
+func newestMTime(paths ...string) time.Time {
+	var newest time.Time
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest
+}
+
+// rebuildSitemapCache regenerates sitemap.xml and writes it to
+// OutputDir. It runs buildSitemap, including its per-dynamic-route
+// plugin builds, so it must only be called off the request path: once
+// after loadRoutes discovers routes, and again on watchEverything's
+// change events.
+func (r *Router) rebuildSitemapCache() {
+	if !r.config.CacheEnabled {
+		return
+	}
+	xml := r.buildSitemap()
+	_ = os.MkdirAll(r.config.OutputDir, 0755)
+	_ = os.WriteFile(filepath.Join(r.config.OutputDir, "sitemap.xml"), xml, 0644)
+}
+
+// serveSitemap serves the sitemap that rebuildSitemapCache last wrote.
+// If caching is disabled (or the cache hasn't been built yet) it falls
+// back to building on demand.
+func (r *Router) serveSitemap(w http.ResponseWriter) {
+	cachePath := filepath.Join(r.config.OutputDir, "sitemap.xml")
+
+	if r.config.CacheEnabled {
+		if data, err := os.ReadFile(cachePath); err == nil {
+			w.Header().Set("Content-Type", "application/xml")
+			w.Write(data)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write(r.buildSitemap())
+}